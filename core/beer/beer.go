@@ -0,0 +1,63 @@
+package beer
+
+import (
+	"encoding/xml"
+	"errors"
+)
+
+//Beer representa uma cerveja cadastrada no sistema
+type Beer struct {
+	XMLName  xml.Name `json:"-" xml:"beer"`
+	ID       int64    `json:"id" xml:"id"`
+	Name     string   `json:"name" xml:"name"`
+	Type     int      `json:"type" xml:"type"`
+	Style    int      `json:"style" xml:"style"`
+	ImageURL string   `json:"image_url,omitempty" xml:"image_url,omitempty"`
+}
+
+//Validate verifica se os dados da cerveja são válidos
+func (b *Beer) Validate() error {
+	if b.Name == "" {
+		return errors.New("name is mandatory")
+	}
+	if b.Type == 0 {
+		return errors.New("type is mandatory")
+	}
+	if b.Style == 0 {
+		return errors.New("style is mandatory")
+	}
+	return nil
+}
+
+//ListParams reúne os parâmetros aceitos por List para filtrar, ordenar e
+//paginar o resultado. É preenchida a partir de gen.ListBeersParams, o tipo
+//gerado por oapi-codegen a partir de api/openapi.yaml (ver toListParams em
+//web/handlers/openapi.go)
+type ListParams struct {
+	Style  []int
+	Type   []int
+	Q      string
+	Sort   []string
+	Limit  int
+	Offset int
+}
+
+//UseCase define as operações disponíveis para manipular cervejas
+type UseCase interface {
+	GetAll() ([]*Beer, error)
+	List(p ListParams) ([]*Beer, int, error)
+	Get(id int64) (*Beer, error)
+	Store(b *Beer) error
+	Update(b *Beer) error
+	Remove(id int64) error
+}
+
+//Repository define a forma de persistência das cervejas
+type Repository interface {
+	GetAll() ([]*Beer, error)
+	List(p ListParams) ([]*Beer, int, error)
+	Get(id int64) (*Beer, error)
+	Store(b *Beer) error
+	Update(b *Beer) error
+	Remove(id int64) error
+}
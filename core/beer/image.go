@@ -0,0 +1,42 @@
+package beer
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+//ImageStore abstrai onde a imagem de uma cerveja é armazenada, permitindo
+//trocar a implementação (disco local, S3, etc) sem alterar os handlers
+type ImageStore interface {
+	//Put grava o conteúdo lido de r para a cerveja id e devolve a URL
+	//pública pela qual a imagem pode ser recuperada
+	Put(id int64, r io.Reader, contentType string) (url string, err error)
+	//Get devolve o conteúdo da imagem da cerveja id
+	Get(id int64) (rc io.ReadCloser, contentType string, err error)
+	//Delete remove a imagem associada à cerveja id
+	Delete(id int64) error
+}
+
+//NewImageStoreFromEnv escolhe a implementação de ImageStore de acordo com
+//a variável de ambiente IMAGE_STORE ("fs" ou "s3"), usando o armazenamento
+//local como padrão
+func NewImageStoreFromEnv() (ImageStore, error) {
+	switch os.Getenv("IMAGE_STORE") {
+	case "s3":
+		useSSL, _ := strconv.ParseBool(os.Getenv("S3_USE_SSL"))
+		return NewS3ImageStore(
+			os.Getenv("S3_ENDPOINT"),
+			os.Getenv("S3_ACCESS_KEY"),
+			os.Getenv("S3_SECRET_KEY"),
+			os.Getenv("S3_BUCKET"),
+			useSSL,
+		)
+	default:
+		dir := os.Getenv("IMAGE_STORE_DIR")
+		if dir == "" {
+			dir = "./web/uploads"
+		}
+		return NewFSImageStore(dir, "/uploads")
+	}
+}
@@ -0,0 +1,95 @@
+package beer
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+)
+
+//FSImageStore grava as imagens das cervejas no sistema de arquivos local,
+//abaixo de Dir, nomeando cada arquivo pelo ID da cerveja
+type FSImageStore struct {
+	//Dir é o diretório onde as imagens são gravadas, ex.: ./web/uploads
+	Dir string
+	//BaseURL é o prefixo usado para montar a URL pública devolvida por Put,
+	//ex.: /uploads
+	BaseURL string
+}
+
+//NewFSImageStore cria um FSImageStore, garantindo que dir exista
+func NewFSImageStore(dir, baseURL string) (*FSImageStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FSImageStore{Dir: dir, BaseURL: baseURL}, nil
+}
+
+func (s *FSImageStore) path(id int64, contentType string) string {
+	ext, _ := mime.ExtensionsByType(contentType)
+	suffix := ""
+	if len(ext) > 0 {
+		suffix = ext[0]
+	}
+	return filepath.Join(s.Dir, fmt.Sprintf("%d%s", id, suffix))
+}
+
+//Put grava o conteúdo de r em disco e devolve a URL pública da imagem. Uma
+//imagem anterior com extensão diferente (reupload com outro content type) é
+//removida antes, senão Get passaria a escolher entre os dois arquivos pela
+//ordem lexical do glob em vez de servir o upload mais recente
+func (s *FSImageStore) Put(id int64, r io.Reader, contentType string) (string, error) {
+	if err := s.removeExisting(id); err != nil {
+		return "", err
+	}
+
+	dst := s.path(id, contentType)
+	f, err := os.Create(dst)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return "", err
+	}
+	return s.BaseURL + "/" + filepath.Base(dst), nil
+}
+
+//removeExisting apaga qualquer arquivo id.* já gravado para a cerveja id
+func (s *FSImageStore) removeExisting(id int64) error {
+	matches, err := filepath.Glob(filepath.Join(s.Dir, fmt.Sprintf("%d.*", id)))
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		if err := os.Remove(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Get lê a imagem da cerveja id a partir do disco
+func (s *FSImageStore) Get(id int64) (io.ReadCloser, string, error) {
+	matches, err := filepath.Glob(filepath.Join(s.Dir, fmt.Sprintf("%d.*", id)))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(matches) == 0 {
+		return nil, "", os.ErrNotExist
+	}
+
+	f, err := os.Open(matches[0])
+	if err != nil {
+		return nil, "", err
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(matches[0]))
+	return f, contentType, nil
+}
+
+//Delete remove a imagem da cerveja id do disco
+func (s *FSImageStore) Delete(id int64) error {
+	return s.removeExisting(id)
+}
@@ -0,0 +1,80 @@
+package beer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v6"
+)
+
+//S3ImageStore grava as imagens das cervejas em um bucket compatível com S3
+//(AWS S3, MinIO, DigitalOcean Spaces, etc), usando o ID da cerveja como key
+type S3ImageStore struct {
+	Client   *minio.Client
+	Bucket   string
+	Endpoint string
+	UseSSL   bool
+}
+
+//NewS3ImageStore conecta em endpoint usando as credenciais informadas e
+//garante que bucket exista antes de devolver o store
+func NewS3ImageStore(endpoint, accessKey, secretKey, bucket string, useSSL bool) (*S3ImageStore, error) {
+	client, err := minio.New(endpoint, accessKey, secretKey, useSSL)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := client.BucketExists(bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err := client.MakeBucket(bucket, ""); err != nil {
+			return nil, err
+		}
+	}
+	return &S3ImageStore{Client: client, Bucket: bucket, Endpoint: endpoint, UseSSL: useSSL}, nil
+}
+
+func (s *S3ImageStore) key(id int64) string {
+	return fmt.Sprintf("beer/%d", id)
+}
+
+//Put transmite o conteúdo de r para o bucket (sem bufferizar a imagem
+//inteira em memória) e devolve a URL pública do objeto
+func (s *S3ImageStore) Put(id int64, r io.Reader, contentType string) (string, error) {
+	key := s.key(id)
+	//tamanho -1 faz o minio-go fazer upload multipart conforme o stream vai
+	//sendo lido, em vez de exigir o tamanho total antecipadamente
+	_, err := s.Client.PutObject(
+		s.Bucket, key,
+		r, -1,
+		minio.PutObjectOptions{ContentType: contentType},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	scheme := "http"
+	if s.UseSSL {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/%s/%s", scheme, s.Endpoint, s.Bucket, key), nil
+}
+
+//Get lê o objeto correspondente à cerveja id do bucket
+func (s *S3ImageStore) Get(id int64) (io.ReadCloser, string, error) {
+	obj, err := s.Client.GetObject(s.Bucket, s.key(id), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	info, err := obj.Stat()
+	if err != nil {
+		return nil, "", err
+	}
+	return obj, info.ContentType, nil
+}
+
+//Delete remove o objeto correspondente à cerveja id do bucket
+func (s *S3ImageStore) Delete(id int64) error {
+	return s.Client.RemoveObject(s.Bucket, s.key(id))
+}
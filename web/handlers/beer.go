@@ -1,57 +1,87 @@
 package handlers
 
 import (
-	"encoding/json"
+	"bytes"
 	"html/template"
+	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/codegangsta/negroni"
 	"github.com/eminetto/pos-web-go/core/beer"
+	"github.com/eminetto/pos-web-go/web/handlers/gen"
 	"github.com/gorilla/mux"
 )
 
+//maxImageSize é o tamanho máximo aceito para a imagem de uma cerveja
+const maxImageSize = 5 << 20 // 5MB
+
+//allowedImageTypes restringe os formatos aceitos no upload de imagem
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+//defaultListLimit é usado quando o cliente não informa ?limit
+const defaultListLimit = 20
+
 //a função recebe como terceiro parâmetro a interface
 //ou seja, ela pode receber qualquer coisa que implemente a interface
 //isso é muito útil para escrevermos testes, ou podermos substituir toda a
 //implementação da regra de negócios
-func MakeBeerHandlers(r *mux.Router, n *negroni.Negroni, service beer.UseCase) {
-	r.Handle("/v1/beer", n.With(
-		negroni.Wrap(getAllBeer(service)),
-	)).Methods("GET", "OPTIONS")
+//
+//As rotas do recurso /v1/beer são registradas pelo ServerInterface que
+//implementa o contrato descrito em api/openapi.yaml (ver openapi.go); aqui
+//só ficam o spec, o Swagger UI e as rotas de imagem
+func MakeBeerHandlers(r *mux.Router, n *negroni.Negroni, service beer.UseCase, images beer.ImageStore) {
+	r.Handle("/v1/openapi.yaml", n.With(
+		negroni.WrapFunc(getOpenAPISpec),
+	)).Methods("GET")
+
+	r.Handle("/v1/docs", n.With(
+		negroni.WrapFunc(getSwaggerUI),
+	)).Methods("GET")
+
+	//beerRouter isola as rotas de cerveja num sub-router próprio, para que
+	//beerMetricsMiddleware só instrumente essas rotas mesmo quando o mesmo
+	//*mux.Router também é usado em MakeDebugHandlers (/debug, /metrics)
+	beerRouter := r.NewRoute().Subrouter()
+	beerRouter.Use(beerMetricsMiddleware)
+
+	gen.HandlerWithOptions(&beerAPI{service: service}, gen.GorillaServerOptions{
+		BaseURL:          "/v1",
+		BaseRouter:       beerRouter,
+		ErrorHandlerFunc: negotiatedParamError,
+	})
 
-	r.Handle("/v1/beer/{id}", n.With(
-		negroni.Wrap(getBeer(service)),
-	)).Methods("GET", "OPTIONS")
+	//o roteador gerado só registra o verbo principal de cada operação; os
+	//OPTIONS abaixo preservam o preflight de CORS que /v1/beer e
+	///v1/beer/{id} sempre aceitaram
+	beerRouter.HandleFunc("/v1/beer", noContentOptions).Methods("OPTIONS")
+	beerRouter.HandleFunc("/v1/beer/{id}", noContentOptions).Methods("OPTIONS")
 
-	r.Handle("/v1/beer", n.With(
-		negroni.Wrap(storeBeer(service)),
+	beerRouter.Handle("/v1/beer/{id}/image", n.With(
+		negroni.Wrap(storeBeerImage(service, images)),
 	)).Methods("POST", "OPTIONS")
 
-	r.Handle("/v1/beer/{id}", n.With(
-		negroni.Wrap(updateBeer(service)),
-	)).Methods("PUT", "OPTIONS")
-
-	r.Handle("/v1/beer/{id}", n.With(
-		negroni.Wrap(removeBeer(service)),
-	)).Methods("DELETE", "OPTIONS")
+	beerRouter.Handle("/v1/beer/{id}/image", n.With(
+		negroni.Wrap(getBeerImage(images)),
+	)).Methods("GET", "OPTIONS")
 }
 
-/*
-Para testar:
-curl http://localhost:4000/v1/beer
-*/
-func getAllBeer(service beer.UseCase) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		//analisa o que o usuário requisitou via headers
-		switch r.Header.Get("Accept") {
-		case "application/json":
-			getAllBeerJSON(w, service)
-		default:
-			getAllBeerHTML(w, service)
-		}
+//negotiatedParamError adapta os erros de binding de path/query do roteador
+//gerado (id inválido, parâmetro malformado) ao formato negociado via Accept,
+//em vez do http.Error (texto puro) usado por padrão em gen.HandlerWithOptions
+func negotiatedParamError(w http.ResponseWriter, r *http.Request, err error) {
+	accept := r.Header.Get("Accept")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(formatError(accept, err.Error()))
+}
 
-	})
+//noContentOptions responde ao preflight de CORS sem acionar a regra de negócio
+func noContentOptions(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func getAllBeerHTML(w http.ResponseWriter, service beer.UseCase) {
@@ -81,188 +111,113 @@ func getAllBeerHTML(w http.ResponseWriter, service beer.UseCase) {
 	}
 }
 
-func getAllBeerJSON(w http.ResponseWriter, service beer.UseCase) {
-	all, err := service.GetAll()
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write(formatJSONError(err.Error()))
-		return
-	}
-	//vamos converter o resultado em JSON e gerar a resposta
-	err = json.NewEncoder(w).Encode(all)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write(formatJSONError("Erro convertendo em JSON"))
-		return
-	}
-}
-
 /*
 Para testar:
-curl http://localhost:4000/v1/beer/1
+curl -X "POST" "http://localhost:4000/v1/beer/1/image" \
+     -F "image=@skol.png"
 */
-func getBeer(service beer.UseCase) http.Handler {
+func storeBeerImage(service beer.UseCase, images beer.ImageStore) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		//vamos pegar o ID da URL
-		//na definição do protocolo http, os parâmetros são enviados no formato de texto
-		//por isso precisamos converter em int
-		vars := mux.Vars(r)
-		id, err := strconv.ParseInt(vars["id"], 10, 64)
+		accept := r.Header.Get("Accept")
+
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
 		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
-			w.Write(formatJSONError(err.Error()))
+			w.Write(formatError(accept, err.Error()))
 			return
 		}
+
 		b, err := service.Get(id)
 		if err != nil {
 			w.WriteHeader(http.StatusNotFound)
-			w.Write(formatJSONError(err.Error()))
-			return
-		}
-		//vamos converter o resultado em JSON e gerar a resposta
-		err = json.NewEncoder(w).Encode(b)
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			w.Write(formatJSONError("Erro convertendo em JSON"))
+			w.Write(formatError(accept, err.Error()))
 			return
 		}
-	})
-}
 
-/*
-Para testar:
-curl -X "POST" "http://localhost:4000/v1/beer" \
-     -H 'Accept: application/json' \
-     -H 'Content-Type: application/json' \
-     -d $'{
-  "name": "Skol",
-  "type": 1,
-  "style":2
-}'
-*/
-func storeBeer(service beer.UseCase) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		//vamos pegar os dados enviados pelo usuário via body
-		var b beer.Beer
-		err := json.NewDecoder(r.Body).Decode(&b)
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write(formatJSONError(err.Error()))
+		//limita o tamanho lido do body antes de fazer o parse, para que um
+		//upload acima do limite seja rejeitado durante a leitura e não
+		//apenas depois de já ter sido recebido por inteiro
+		r.Body = http.MaxBytesReader(w, r.Body, maxImageSize)
+		if err := r.ParseMultipartForm(maxImageSize); err != nil {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			w.Write(formatError(accept, err.Error()))
 			return
 		}
 
-		err = b.Validate()
+		file, header, err := r.FormFile("image")
 		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
-			w.Write(formatJSONError(err.Error()))
-			return
-		}
-
-		err = service.Store(&b)
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			w.Write(formatJSONError(err.Error()))
+			w.Write(formatError(accept, err.Error()))
 			return
 		}
-		w.WriteHeader(http.StatusCreated)
-	})
-}
-
-/*
-Para testar:
-curl -X "PUT" "http://localhost:4000/v1/beer/2" \
-     -H 'Accept: application/json' \
-     -H 'Content-Type: application/json' \
-     -d $'{
-  "name": "Alterada",
-  "type": 3,
-  "style":1
-}'
-*/
-func updateBeer(service beer.UseCase) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-
-		// recebe o id que será alterado
-		data := mux.Vars(r)
-
-		id, err := strconv.ParseInt(data["id"], 10, 64)
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write(formatJSONError(err.Error()))
+		defer file.Close()
 
+		if header.Size > maxImageSize {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			w.Write(formatError(accept, "image exceeds the maximum allowed size"))
 			return
 		}
 
-		oldBeer, err := service.Get(id)
-		if err != nil {
-			w.WriteHeader(http.StatusNotFound)
-			w.Write(formatJSONError(err.Error()))
-
+		head := make([]byte, 512)
+		n, err := file.Read(head)
+		if err != nil && err != io.EOF {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write(formatError(accept, err.Error()))
 			return
 		}
-
-		// Recebe os dados do Put
-		var newBeer beer.Beer
-		err = json.NewDecoder(r.Body).Decode(&newBeer)
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write(formatJSONError(err.Error()))
-
+		contentType := http.DetectContentType(head[:n])
+		if !allowedImageTypes[contentType] {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			w.Write(formatError(accept, "unsupported image type: "+contentType))
 			return
 		}
 
-		err = newBeer.Validate()
+		body := io.MultiReader(bytes.NewReader(head[:n]), file)
+		url, err := images.Put(id, body, contentType)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write(formatJSONError(err.Error()))
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write(formatError(accept, err.Error()))
 			return
 		}
 
-		oldBeer.Name = newBeer.Name
-		oldBeer.Style = newBeer.Style
-		oldBeer.Type = newBeer.Type
-
-		err = service.Update(oldBeer)
-		if err != nil {
+		b.ImageURL = url
+		if err := service.Update(b); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			w.Write(formatJSONError(err.Error()))
-
+			w.Write(formatError(accept, err.Error()))
 			return
 		}
 
-		w.WriteHeader(http.StatusNoContent)
+		w.WriteHeader(http.StatusCreated)
+		render(w, accept, struct {
+			URL string `json:"url" xml:"url"`
+		}{URL: url})
 	})
 }
 
 /*
 Para testar:
-curl -X "DELETE" "http://localhost:4000/v1/beer/2" \
-     -H 'Accept: application/json' \
-     -H 'Content-Type: application/json'
+curl http://localhost:4000/v1/beer/1/image
 */
-func removeBeer(service beer.UseCase) http.Handler {
+func getBeerImage(images beer.ImageStore) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept := r.Header.Get("Accept")
 
-		// Recebe o id
-		data := mux.Vars(r)
-		id, err := strconv.ParseInt(data["id"], 10, 64)
-
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
 		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
-			w.Write(formatJSONError(err.Error()))
-
+			w.Write(formatError(accept, err.Error()))
 			return
 		}
 
-		err = service.Remove(id)
-
+		rc, contentType, err := images.Get(id)
 		if err != nil {
 			w.WriteHeader(http.StatusNotFound)
-			w.Write(formatJSONError(err.Error()))
-
+			w.Write(formatError(accept, err.Error()))
 			return
 		}
+		defer rc.Close()
 
-		w.WriteHeader(http.StatusNoContent)
+		w.Header().Set("Content-Type", contentType)
+		io.Copy(w, rc)
 	})
 }
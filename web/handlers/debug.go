@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"strings"
+
+	"github.com/codegangsta/negroni"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+//Authenticator decide se uma requisição para as rotas de debug pode
+//prosseguir, além do check de origem loopback já feito por MakeDebugHandlers
+type Authenticator interface {
+	Authenticate(r *http.Request) bool
+}
+
+//debugAuthTokenEnv é a variável de ambiente que, se definida, libera o
+//acesso às rotas de debug para clientes fora do loopback que enviarem o
+//mesmo valor no header Authorization
+const debugAuthTokenEnv = "DEBUG_AUTH_TOKEN"
+
+//MakeDebugHandlers expõe net/http/pprof e métricas Prometheus por trás de
+//uma checagem de origem: só responde para loopback, para quem apresenta o
+//token configurado em DEBUG_AUTH_TOKEN, ou para quem auth aprovar
+func MakeDebugHandlers(r *mux.Router, n *negroni.Negroni, auth Authenticator) {
+	debug := r.PathPrefix("/debug/pprof").Subrouter()
+	debug.Use(guardDebugAccess(auth))
+
+	debug.HandleFunc("/", pprof.Index)
+	debug.HandleFunc("/cmdline", pprof.Cmdline)
+	debug.HandleFunc("/profile", pprof.Profile) //perfil de CPU, duração via ?seconds=
+	debug.HandleFunc("/symbol", pprof.Symbol)
+	debug.HandleFunc("/trace", pprof.Trace)
+	for _, profile := range []string{"heap", "goroutine", "block", "mutex", "allocs"} {
+		debug.Handle("/"+profile, pprof.Handler(profile))
+	}
+
+	//metrics também precisa da mesma checagem de origem que /debug/pprof,
+	//não apenas a rota de profiling
+	r.Handle("/metrics", n.With(
+		negroni.Wrap(guardDebugAccess(auth)(promhttp.Handler())),
+	)).Methods("GET")
+}
+
+//guardDebugAccess só deixa passar requisições vindas do loopback ou que
+//apresentem o token configurado em DEBUG_AUTH_TOKEN (ou que auth aprove),
+//permitindo habilitar as rotas de debug com segurança em produção
+func guardDebugAccess(auth Authenticator) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isLoopback(r) || hasValidDebugToken(r) || (auth != nil && auth.Authenticate(r)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			http.Error(w, "forbidden", http.StatusForbidden)
+		})
+	}
+}
+
+//isLoopback confia em r.RemoteAddr, que é o endereço do peer TCP direto.
+//Atrás de um reverse proxy no mesmo host esse peer é sempre o proxy, não o
+//cliente real — nesse cenário DEBUG_AUTH_TOKEN (ou um Authenticator que
+//valide algo como X-Forwarded-For de forma confiável) deixa de ser opcional
+func isLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func hasValidDebugToken(r *http.Request) bool {
+	token := os.Getenv(debugAuthTokenEnv)
+	if token == "" {
+		return false
+	}
+	presented := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	//comparação em tempo constante: o token protege rotas de debug/profiling
+	//em produção e não deve ser vulnerável a timing attacks
+	return subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1
+}
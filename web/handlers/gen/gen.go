@@ -0,0 +1,7 @@
+// Package gen contém os tipos e o roteador HTTP gerados por oapi-codegen a
+// partir de api/openapi.yaml. types.go e server.go são gerados — não edite
+// esses arquivos à mão, rode `go generate ./...` depois de alterar o spec.
+package gen
+
+//go:generate go tool oapi-codegen -generate types -package gen -o types.go ../../../api/openapi.yaml
+//go:generate go tool oapi-codegen -generate gorilla -package gen -o server.go ../../../api/openapi.yaml
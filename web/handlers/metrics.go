@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	beerRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "beer_http_requests_total",
+		Help: "Total de requisições recebidas pelas rotas de cerveja",
+	}, []string{"method", "route", "status"})
+
+	beerRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "beer_http_request_duration_seconds",
+		Help: "Duração das requisições às rotas de cerveja",
+	}, []string{"method", "route", "status"})
+)
+
+//responseRecorder captura o status code escrito por um handler, já que
+//http.ResponseWriter não expõe essa informação depois de enviada
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+//beerMetricsMiddleware registra contadores e histogramas por método, rota
+//e status para as requisições que passam pelo router de cervejas
+func beerMetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+
+		next.ServeHTTP(rec, r)
+
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+		labels := prometheus.Labels{
+			"method": r.Method,
+			"route":  route,
+			"status": strconv.Itoa(rec.status),
+		}
+		beerRequestsTotal.With(labels).Inc()
+		beerRequestDuration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
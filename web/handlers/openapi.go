@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/eminetto/pos-web-go/core/beer"
+	"github.com/eminetto/pos-web-go/web/handlers/gen"
+)
+
+//beerList é o elemento raiz emitido ao listar cervejas em XML: uma sequência
+//de <beer>...</beer> sem um elemento pai não é XML bem formado
+type beerList struct {
+	XMLName xml.Name     `json:"-" xml:"beers"`
+	Beers   []*beer.Beer `xml:"beer"`
+}
+
+//openAPISpec é o caminho, relativo à raiz do projeto, para a especificação
+//que descreve o contrato HTTP exposto em /v1
+const openAPISpec = "./api/openapi.yaml"
+
+//beerAPI implementa gen.ServerInterface, ligando as rotas e os parâmetros
+//gerados a partir de api/openapi.yaml ao beer.UseCase
+type beerAPI struct {
+	service beer.UseCase
+}
+
+//toListParams converte os parâmetros de query já extraídos pelo roteador
+//gerado para o tipo que beer.UseCase espera, aplicando o default de limit
+func toListParams(params gen.ListBeersParams) beer.ListParams {
+	p := beer.ListParams{Limit: defaultListLimit}
+	if params.Style != nil {
+		p.Style = *params.Style
+	}
+	if params.Type != nil {
+		p.Type = *params.Type
+	}
+	if params.Q != nil {
+		p.Q = *params.Q
+	}
+	if params.Sort != nil {
+		p.Sort = *params.Sort
+	}
+	if params.Limit != nil {
+		p.Limit = *params.Limit
+	}
+	if params.Offset != nil {
+		p.Offset = *params.Offset
+	}
+	return p
+}
+
+func (a *beerAPI) ListBeers(w http.ResponseWriter, r *http.Request, params gen.ListBeersParams) {
+	accept := r.Header.Get("Accept")
+	mt := mediaType(accept)
+	if mt != mimeJSON && mt != mimeXML && mt != mimeTextXML {
+		getAllBeerHTML(w, a.service)
+		return
+	}
+
+	p := toListParams(params)
+	all, total, err := a.service.List(p)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(formatError(accept, err.Error()))
+		return
+	}
+
+	w.Header().Set("X-Total-Count", fmt.Sprintf("%d", total))
+	if link := buildLinkHeader(r.URL, p, total); link != "" {
+		w.Header().Set("Link", link)
+	}
+
+	//em XML, []*beer.Beer precisa de um elemento raiz; em JSON o array vai
+	//como está, então só trocamos o payload quando o formato negociado é XML
+	var payload interface{} = all
+	if mt == mimeXML || mt == mimeTextXML {
+		payload = beerList{Beers: all}
+	}
+	if err := render(w, accept, payload); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(formatError(accept, "Erro convertendo resposta"))
+	}
+}
+
+func (a *beerAPI) CreateBeer(w http.ResponseWriter, r *http.Request) {
+	accept := r.Header.Get("Accept")
+
+	var b beer.Beer
+	if err := decodeBody(r, &b); err != nil {
+		status := http.StatusBadRequest
+		if err == errUnsupportedMediaType {
+			status = http.StatusUnsupportedMediaType
+		}
+		w.WriteHeader(status)
+		w.Write(formatError(accept, err.Error()))
+		return
+	}
+
+	if err := b.Validate(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(formatError(accept, err.Error()))
+		return
+	}
+
+	if err := a.service.Store(&b); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(formatError(accept, err.Error()))
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (a *beerAPI) FindBeerById(w http.ResponseWriter, r *http.Request, id int64) {
+	accept := r.Header.Get("Accept")
+
+	b, err := a.service.Get(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write(formatError(accept, err.Error()))
+		return
+	}
+	if err := render(w, accept, b); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(formatError(accept, "Erro convertendo resposta"))
+	}
+}
+
+func (a *beerAPI) UpdateBeer(w http.ResponseWriter, r *http.Request, id int64) {
+	accept := r.Header.Get("Accept")
+
+	oldBeer, err := a.service.Get(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write(formatError(accept, err.Error()))
+		return
+	}
+
+	var newBeer beer.Beer
+	if err := decodeBody(r, &newBeer); err != nil {
+		status := http.StatusBadRequest
+		if err == errUnsupportedMediaType {
+			status = http.StatusUnsupportedMediaType
+		}
+		w.WriteHeader(status)
+		w.Write(formatError(accept, err.Error()))
+		return
+	}
+
+	if err := newBeer.Validate(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(formatError(accept, err.Error()))
+		return
+	}
+
+	oldBeer.Name = newBeer.Name
+	oldBeer.Style = newBeer.Style
+	oldBeer.Type = newBeer.Type
+
+	if err := a.service.Update(oldBeer); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(formatError(accept, err.Error()))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *beerAPI) DeleteBeer(w http.ResponseWriter, r *http.Request, id int64) {
+	accept := r.Header.Get("Accept")
+
+	if err := a.service.Remove(id); err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write(formatError(accept, err.Error()))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+//buildLinkHeader monta os links rel=next/prev descritos na RFC 5988 a
+//partir da página atual e do total de registros
+func buildLinkHeader(base *url.URL, p beer.ListParams, total int) string {
+	var links []string
+	if p.Offset+p.Limit < total {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(base, p.Offset+p.Limit, p.Limit)))
+	}
+	if p.Offset > 0 {
+		prevOffset := p.Offset - p.Limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(base, prevOffset, p.Limit)))
+	}
+	return strings.Join(links, ", ")
+}
+
+func pageURL(base *url.URL, offset, limit int) string {
+	u := *base
+	q := u.Query()
+	q.Set("offset", strconv.Itoa(offset))
+	q.Set("limit", strconv.Itoa(limit))
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+/*
+Para testar:
+curl http://localhost:4000/v1/openapi.yaml
+curl http://localhost:4000/v1/docs
+*/
+func getOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	http.ServeFile(w, r, openAPISpec)
+}
+
+func getSwaggerUI(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, swaggerUIHTML)
+}
+
+//swaggerUIHTML carrega o Swagger UI via CDN apontando para o nosso spec,
+//dispensando empacotar os assets estáticos da ferramenta
+const swaggerUIHTML = `<!DOCTYPE html>
+<html>
+<head>
+  <title>pos-web-go beer API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist/swagger-ui-bundle.js"></script>
+  <script>
+    window.onload = function() {
+      SwaggerUIBundle({url: '/v1/openapi.yaml', dom_id: '#swagger-ui'})
+    }
+  </script>
+</body>
+</html>`
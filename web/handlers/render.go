@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"mime"
+	"net/http"
+)
+
+//errUnsupportedMediaType é retornado por decodeBody quando o Content-Type
+//enviado não é um dos formatos suportados
+var errUnsupportedMediaType = errors.New("unsupported media type")
+
+//errEmptyBody é retornado por decodeBody quando o body da requisição está vazio
+var errEmptyBody = errors.New("empty body")
+
+//mimeJSON é o formato JSON; mimeXML e mimeTextXML cobrem as variações de XML
+//aceitas pelos clientes
+const (
+	mimeJSON    = "application/json"
+	mimeXML     = "application/xml"
+	mimeTextXML = "text/xml"
+)
+
+//mediaType extrai o media type de um header Content-Type/Accept, ignorando
+//parâmetros como charset ou q (ex.: "application/xml; q=0.9"). Se o header
+//não puder ser interpretado, devolve o valor original para que o switch de
+//quem chamou caia no caso default
+func mediaType(header string) string {
+	if header == "" {
+		return ""
+	}
+	parsed, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return header
+	}
+	return parsed
+}
+
+//render escreve v no formato solicitado pelo header Accept, usando JSON
+//como formato padrão quando nenhum dos formatos suportados é pedido
+func render(w http.ResponseWriter, accept string, v interface{}) error {
+	switch mt := mediaType(accept); mt {
+	case mimeXML, mimeTextXML:
+		w.Header().Set("Content-Type", mt)
+		return xml.NewEncoder(w).Encode(v)
+	default:
+		w.Header().Set("Content-Type", mimeJSON)
+		return json.NewEncoder(w).Encode(v)
+	}
+}
+
+//decodeBody lê o body da requisição em dst de acordo com o Content-Type
+//enviado, retornando erro caso o tipo não seja suportado. Parâmetros extras
+//do header (ex.: "application/json; charset=utf-8") são ignorados, já que
+//só o media type importa para escolher o decoder
+func decodeBody(r *http.Request, dst interface{}) error {
+	if r.ContentLength == 0 {
+		return errEmptyBody
+	}
+	switch mediaType(r.Header.Get("Content-Type")) {
+	case mimeXML, mimeTextXML:
+		return xml.NewDecoder(r.Body).Decode(dst)
+	case mimeJSON, "":
+		return json.NewDecoder(r.Body).Decode(dst)
+	default:
+		return errUnsupportedMediaType
+	}
+}
+
+//formatError gera o corpo de uma resposta de erro no formato negociado
+//via header Accept, substituindo o antigo formatJSONError
+func formatError(accept, msg string) []byte {
+	type errorResponse struct {
+		XMLName xml.Name `json:"-" xml:"error"`
+		Error   string   `json:"error" xml:"message"`
+	}
+	e := errorResponse{Error: msg}
+	switch mediaType(accept) {
+	case mimeXML, mimeTextXML:
+		b, _ := xml.Marshal(e)
+		return b
+	default:
+		b, _ := json.Marshal(e)
+		return b
+	}
+}